@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsStashPageSize is how many documents Fetch returns per page, mirroring
+// the paging behavior of the Charm API.
+const fsStashPageSize = 100
+
+// fsStashSource serves the stash from a set of local directories instead
+// of a Charm account, so glow can run without a network connection or an
+// SSH keypair. Directories are indexed recursively for *.md files and
+// watched so changes made outside of glow show up without a restart.
+type fsStashSource struct {
+	dirs    []string
+	watcher *fsnotify.Watcher
+
+	mu     sync.Mutex
+	byID   map[int]*document
+	nextID int
+}
+
+// newFSStashSource indexes dirs and starts watching them for changes.
+func newFSStashSource(dirs []string) (*fsStashSource, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not start filesystem watcher: %w", err)
+	}
+
+	s := &fsStashSource{
+		dirs:    dirs,
+		watcher: watcher,
+		byID:    make(map[int]*document),
+	}
+
+	if err := s.reindex(); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// reindex walks s.dirs and (re)builds the in-memory document index. Existing
+// IDs are preserved by path so open documents don't change identity. Every
+// directory discovered along the way is (re-)added to the watcher, since
+// fsnotify doesn't watch recursively on its own and new subdirectories can
+// appear between reindexes; adding an already-watched directory is a
+// harmless no-op.
+func (s *fsStashSource) reindex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byPath := make(map[string]*document, len(s.byID))
+	for _, doc := range s.byID {
+		byPath[doc.Path] = doc
+	}
+
+	s.byID = make(map[int]*document)
+
+	for _, dir := range s.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if err := s.watcher.Add(path); err != nil {
+					return fmt.Errorf("could not watch %s: %w", path, err)
+				}
+				return nil
+			}
+			if !strings.EqualFold(filepath.Ext(path), ".md") {
+				return nil
+			}
+
+			doc, ok := byPath[path]
+			if !ok {
+				s.nextID++
+				doc = &document{ID: s.nextID, Path: path}
+			}
+			doc.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			s.byID[doc.ID] = doc
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not index %s: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// Events returns the underlying fsnotify event channel so the caller can
+// trigger a reindex (and a UI refresh) when files change on disk.
+func (s *fsStashSource) Events() <-chan fsnotify.Event {
+	return s.watcher.Events
+}
+
+// Fetch returns the page-th page of documents, sorted by path since local
+// Markdown files have no upstream notion of recency to sort by instead.
+// Sorting (rather than ranging over the map directly) keeps the stash
+// listing's order stable across calls.
+func (s *fsStashSource) Fetch(page int) ([]*document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs := make([]*document, 0, len(s.byID))
+	for _, doc := range s.byID {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+
+	start := page * fsStashPageSize
+	if start >= len(docs) {
+		return nil, nil
+	}
+	end := start + fsStashPageSize
+	if end > len(docs) {
+		end = len(docs)
+	}
+	return docs[start:end], nil
+}
+
+func (s *fsStashSource) Load(doc *document) (*document, error) {
+	b, err := os.ReadFile(doc.Path)
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = string(b)
+	return doc, nil
+}
+
+// SaveNote has no analog for local files, so the note is only kept in
+// memory for the session; the Markdown file on disk is not rewritten.
+func (s *fsStashSource) SaveNote(id int, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.byID[id]
+	if !ok {
+		return fmt.Errorf("no such document: %d", id)
+	}
+	doc.Note = note
+	return nil
+}
+
+func (s *fsStashSource) Delete(id int) error {
+	s.mu.Lock()
+	doc, ok := s.byID[id]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no such document: %d", id)
+	}
+	if err := os.Remove(doc.Path); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.byID, id)
+	s.mu.Unlock()
+	return nil
+}
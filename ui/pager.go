@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"github.com/charmbracelet/boba"
+	"github.com/charmbracelet/charm"
+	"github.com/muesli/reflow/indent"
+)
+
+type pagerState int
+
+const (
+	pagerStateBrowse pagerState = iota
+)
+
+// fetchedMarkdownMsg delivers a document with its full body loaded, ready
+// to render. It's a named *document so callers can read msg.Body, msg.ID,
+// etc. directly.
+type fetchedMarkdownMsg *document
+
+// contentRenderedMsg carries a document's body after it's been run through
+// a Renderer, ready to display in the pager viewport.
+type contentRenderedMsg string
+
+// pagerModel displays a single rendered document. It's source-agnostic:
+// it only ever deals with the already-fetched fetchedMarkdownMsg and the
+// Renderer it's configured with, regardless of where the document came
+// from.
+type pagerModel struct {
+	cc              *charm.Client
+	state           pagerState
+	style           string
+	renderer        Renderer
+	currentDocument fetchedMarkdownMsg
+	viewport        string
+	width           int
+	height          int
+
+	// highlightTerms, when set, are the search terms to highlight in the
+	// rendered viewport -- set when a document is opened from a search
+	// result, so the pager shows the same matches the search list did.
+	highlightTerms []string
+}
+
+func newPagerModel(style string) pagerModel {
+	return pagerModel{
+		state:    pagerStateBrowse,
+		style:    style,
+		renderer: newGlamourRenderer(style, 0),
+	}
+}
+
+func (m *pagerModel) setSize(w, h int) {
+	m.width = w
+	m.height = h
+	if r, ok := m.renderer.(*glamourRenderer); ok {
+		r.width = w
+	}
+}
+
+func (m *pagerModel) unload() {
+	m.currentDocument = nil
+	m.viewport = ""
+	m.state = pagerStateBrowse
+}
+
+// renderWithGlamour renders markdown with the pager's configured Renderer
+// (Glamour by default) and reports the result as a contentRenderedMsg.
+func renderWithGlamour(m pagerModel, markdown string) boba.Cmd {
+	return func() boba.Msg {
+		out, err := m.renderer.Render(markdown)
+		if err != nil {
+			return errMsg(err)
+		}
+		return contentRenderedMsg(out)
+	}
+}
+
+func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
+	switch msg := msg.(type) {
+	case contentRenderedMsg:
+		m.viewport = highlightRendered(string(msg), m.highlightTerms)
+	}
+	return m, nil
+}
+
+func pagerView(m pagerModel) string {
+	if m.currentDocument == nil {
+		return "\n" + indent.String("Nothing to show.", 2)
+	}
+	return m.viewport
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/boba"
+	"github.com/charmbracelet/glamour"
+	"github.com/yuin/goldmark"
+)
+
+// exportFormat identifies an output format a Renderer can produce.
+type exportFormat string
+
+const (
+	formatANSI exportFormat = "ansi"
+	formatHTML exportFormat = "html"
+	formatPDF  exportFormat = "pdf"
+)
+
+// parseExportFormat maps a user-supplied format name (as typed after "e" in
+// the pager, or passed via --export) to an exportFormat.
+func parseExportFormat(s string) (exportFormat, error) {
+	switch s {
+	case "ansi", "":
+		return formatANSI, nil
+	case "html":
+		return formatHTML, nil
+	case "pdf":
+		return formatPDF, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want ansi, html, or pdf)", s)
+}
+
+// Renderer turns a document's Markdown body into a particular output
+// format. The pager uses glamourRenderer to render to the terminal;
+// exporting uses htmlRenderer or pdfRenderer instead.
+type Renderer interface {
+	Render(markdown string) ([]byte, error)
+}
+
+// glamourRenderer renders Markdown to ANSI for display in the pager. This
+// is the renderer renderWithGlamour has always used, just behind the
+// Renderer interface so the pager can swap it out for exporting.
+type glamourRenderer struct {
+	style string
+	width int
+}
+
+func newGlamourRenderer(style string, width int) *glamourRenderer {
+	return &glamourRenderer{style: style, width: width}
+}
+
+func (r *glamourRenderer) Render(markdown string) ([]byte, error) {
+	out, err := glamour.RenderWithWordWrap(markdown, r.style, r.width)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// htmlRenderer renders Markdown to a standalone HTML document using
+// goldmark, for the "e" export flow and as an intermediate step for PDF
+// export.
+type htmlRenderer struct{}
+
+func newHTMLRenderer() *htmlRenderer {
+	return &htmlRenderer{}
+}
+
+func (r *htmlRenderer) Render(markdown string) ([]byte, error) {
+	var body bytes.Buffer
+	if err := goldmark.Convert([]byte(markdown), &body); err != nil {
+		return nil, err
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"></head><body>\n")
+	doc.Write(body.Bytes())
+	doc.WriteString("\n</body></html>\n")
+	return doc.Bytes(), nil
+}
+
+// pdfRenderer renders Markdown to PDF by first rendering to HTML and then
+// piping that through wkhtmltopdf, which must be on PATH.
+type pdfRenderer struct {
+	html *htmlRenderer
+}
+
+func newPDFRenderer() *pdfRenderer {
+	return &pdfRenderer{html: newHTMLRenderer()}
+}
+
+func (r *pdfRenderer) Render(markdown string) ([]byte, error) {
+	html, err := r.html.Render(markdown)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("wkhtmltopdf", "-", "-")
+	cmd.Stdin = bytes.NewReader(html)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("wkhtmltopdf: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// rendererFor returns the Renderer to use for a given export format.
+func rendererFor(format exportFormat, style string, width int) Renderer {
+	switch format {
+	case formatHTML:
+		return newHTMLRenderer()
+	case formatPDF:
+		return newPDFRenderer()
+	default:
+		return newGlamourRenderer(style, width)
+	}
+}
+
+// writeExport renders markdown with the given format and writes the result
+// to destPath.
+func writeExport(format exportFormat, style string, width int, markdown string, destPath string) error {
+	out, err := rendererFor(format, style, width).Render(markdown)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destPath, out, 0o644)
+}
+
+// doExport runs writeExport asynchronously and reports completion so the
+// pager can show progress (a spinner, currently) instead of blocking.
+func doExport(format exportFormat, style string, width int, markdown string, destPath string) boba.Cmd {
+	return func() boba.Msg {
+		if err := writeExport(format, style, width, markdown, destPath); err != nil {
+			return errMsg(err)
+		}
+		return exportDoneMsg{path: destPath}
+	}
+}
+
+// scriptedExport backs the non-interactive `glow --export=pdf file.md`
+// flow: it renders cfg.ExportSourcePath with cfg.ExportFormat and writes it
+// to cfg.ExportDestPath (deriving a destination from the source path if
+// one wasn't given), with no stash or pager involved.
+func scriptedExport(cfg Config) boba.Cmd {
+	return func() boba.Msg {
+		format, err := parseExportFormat(cfg.ExportFormat)
+		if err != nil {
+			return errMsg(err)
+		}
+
+		markdown, err := os.ReadFile(cfg.ExportSourcePath)
+		if err != nil {
+			return errMsg(fmt.Errorf("could not read %s: %w", cfg.ExportSourcePath, err))
+		}
+
+		dest := cfg.ExportDestPath
+		if dest == "" {
+			ext := filepath.Ext(cfg.ExportSourcePath)
+			dest = strings.TrimSuffix(cfg.ExportSourcePath, ext) + "." + string(format)
+		}
+
+		if err := writeExport(format, cfg.Style, 80, string(markdown), dest); err != nil {
+			return errMsg(err)
+		}
+		return scriptedExportDoneMsg{path: dest}
+	}
+}
@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchField identifies which part of a document a token came from, so
+// hits can be weighted and snippets can be built from the right text.
+type searchField int
+
+const (
+	fieldTitle searchField = iota
+	fieldNote
+	fieldBody
+)
+
+// bm25 tuning parameters. k1 controls term-frequency saturation, b controls
+// how much document length normalizes the score.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type posting struct {
+	docID int
+	field searchField
+	freq  int
+}
+
+// searchIndex is a small in-memory inverted index over the stash: document
+// titles, notes, and bodies are tokenized and indexed so the search mode
+// can filter and rank the stash list as the user types.
+type searchIndex struct {
+	postings map[string][]posting
+	docLen   map[int]int // token count per document, for BM25 length norm
+	avgLen   float64
+	docs     map[int]*document
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string][]posting),
+		docLen:   make(map[int]int),
+		docs:     make(map[int]*document),
+	}
+}
+
+// Add indexes (or re-indexes) a document's title, note, and body.
+func (x *searchIndex) Add(doc *document) {
+	x.Remove(doc.ID)
+	x.docs[doc.ID] = doc
+
+	total := 0
+	total += x.index(doc.ID, fieldTitle, doc.Title)
+	total += x.index(doc.ID, fieldNote, doc.Note)
+	total += x.index(doc.ID, fieldBody, doc.Body)
+	x.docLen[doc.ID] = total
+
+	x.recomputeAvgLen()
+}
+
+// Remove drops a document from the index, e.g. after a delete.
+func (x *searchIndex) Remove(id int) {
+	if _, ok := x.docs[id]; !ok {
+		return
+	}
+	delete(x.docs, id)
+	delete(x.docLen, id)
+	for token, postings := range x.postings {
+		filtered := postings[:0]
+		for _, p := range postings {
+			if p.docID != id {
+				filtered = append(filtered, p)
+			}
+		}
+		if len(filtered) == 0 {
+			delete(x.postings, token)
+		} else {
+			x.postings[token] = filtered
+		}
+	}
+	x.recomputeAvgLen()
+}
+
+func (x *searchIndex) recomputeAvgLen() {
+	if len(x.docLen) == 0 {
+		x.avgLen = 0
+		return
+	}
+	sum := 0
+	for _, n := range x.docLen {
+		sum += n
+	}
+	x.avgLen = float64(sum) / float64(len(x.docLen))
+}
+
+func (x *searchIndex) index(id int, field searchField, text string) int {
+	counts := make(map[string]int)
+	tokens := tokenize(text)
+	for _, t := range tokens {
+		counts[t]++
+	}
+	for token, freq := range counts {
+		x.postings[token] = append(x.postings[token], posting{docID: id, field: field, freq: freq})
+	}
+	return len(tokens)
+}
+
+// tokenize splits on unicode word boundaries, lowercases, and applies a
+// simple suffix stemmer (plurals and -ing/-ed) good enough for matching
+// casual notes and Markdown prose.
+func tokenize(text string) []string {
+	var tokens []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, stem(strings.ToLower(b.String())))
+			b.Reset()
+		}
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func stem(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 4:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "ing") && len(s) > 5:
+		return s[:len(s)-3]
+	case strings.HasSuffix(s, "ed") && len(s) > 4:
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "es") && len(s) > 4:
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && len(s) > 3:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// searchHit is one ranked result from a query.
+type searchHit struct {
+	Doc   *document
+	Score float64
+}
+
+// Search tokenizes the query and ranks matching documents by BM25. A
+// trailing token without a following space is treated as a prefix, so
+// results update as the user is still typing the last word. A quoted
+// query ("like this") is treated as a phrase: all tokens must appear
+// consecutively, in order, within the same field.
+func (x *searchIndex) Search(query string) []searchHit {
+	prefix := !strings.HasSuffix(query, " ")
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`) && len(query) > 1 {
+		return x.phraseSearch(strings.Trim(query, `"`))
+	}
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for i, term := range terms {
+		expanded := x.expand(term, prefix && i == len(terms)-1)
+		tokens := make([]string, 0, len(expanded))
+		for token := range expanded {
+			tokens = append(tokens, token)
+		}
+		for id, score := range x.scoreMatches(nil, tokens) {
+			scores[id] += score
+		}
+	}
+
+	return rank(scores, x.docs)
+}
+
+// expand returns every indexed token equal to (or, if prefix is true,
+// prefixed by) term.
+func (x *searchIndex) expand(term string, prefix bool) map[string]bool {
+	matches := make(map[string]bool)
+	if !prefix {
+		if _, ok := x.postings[term]; ok {
+			matches[term] = true
+		}
+		return matches
+	}
+	for token := range x.postings {
+		if strings.HasPrefix(token, term) {
+			matches[token] = true
+		}
+	}
+	return matches
+}
+
+// phraseSearch restricts results to documents containing terms
+// consecutively and in order in at least one field, then scores those matches with the same BM25 formula
+// as a plain multi-term query so phrase and term queries rank comparably.
+func (x *searchIndex) phraseSearch(phrase string) []searchHit {
+	terms := tokenize(phrase)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	matches := make(map[int]bool)
+	for id, doc := range x.docs {
+		for _, text := range []string{doc.Title, doc.Note, doc.Body} {
+			if containsPhrase(tokenize(text), terms) {
+				matches[id] = true
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	return rank(x.scoreMatches(matches, terms), x.docs)
+}
+
+// scoreMatches applies the BM25 formula to every posting for tokens,
+// accumulating each matching document's score. If matches is non-nil, only
+// documents it contains are scored; this is what lets Search and
+// phraseSearch share one copy of the scoring math instead of each keeping
+// their own.
+func (x *searchIndex) scoreMatches(matches map[int]bool, tokens []string) map[int]float64 {
+	scores := make(map[int]float64)
+	n := float64(len(x.docs))
+	for _, token := range tokens {
+		postings := x.postings[token]
+		df := float64(len(postings))
+		if df == 0 || n == 0 {
+			continue
+		}
+		idf := bm25IDF(n, df)
+		for _, p := range postings {
+			if matches != nil && !matches[p.docID] {
+				continue
+			}
+			tf := float64(p.freq)
+			dl := float64(x.docLen[p.docID])
+			norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*dl/x.avgLen))
+			scores[p.docID] += idf * norm * fieldWeight(p.field)
+		}
+	}
+	return scores
+}
+
+func containsPhrase(tokens, phrase []string) bool {
+	if len(phrase) > len(tokens) {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, p := range phrase {
+			if tokens[i+j] != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func bm25IDF(n, df float64) float64 {
+	v := (n-df+0.5)/(df+0.5) + 1
+	if v <= 0 {
+		return 0
+	}
+	return math.Log(v)
+}
+
+// fieldWeight boosts matches in the title and note over the body, since a
+// hit in either is usually a stronger signal of relevance.
+func fieldWeight(f searchField) float64 {
+	switch f {
+	case fieldTitle:
+		return 3
+	case fieldNote:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func rank(scores map[int]float64, docs map[int]*document) []searchHit {
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, searchHit{Doc: docs[id], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	return hits
+}
@@ -0,0 +1,103 @@
+package ui
+
+import "testing"
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"running cats", []string{"runn", "cat"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := tokenize(tt.in)
+		if !equalStrings(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"cats":     "cat",
+		"parties":  "party",
+		"running":  "runn",
+		"wanted":   "want",
+		"boxes":    "box",
+		"markdown": "markdown",
+	}
+	for in, want := range tests {
+		if got := stem(in); got != want {
+			t.Errorf("stem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchRanksExactTitleMatchFirst(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Add(&document{ID: 1, Title: "grocery list", Body: "milk, eggs, bread"})
+	idx.Add(&document{ID: 2, Title: "meeting notes", Body: "discussed the grocery budget"})
+
+	hits := idx.Search("grocery")
+	if len(hits) != 2 {
+		t.Fatalf("Search(%q) returned %d hits, want 2", "grocery", len(hits))
+	}
+	if hits[0].Doc.ID != 1 {
+		t.Errorf("top hit = doc %d, want doc 1 (title match should outrank body match)", hits[0].Doc.ID)
+	}
+	if hits[0].Score <= hits[1].Score {
+		t.Errorf("top hit score %v should be greater than runner-up score %v", hits[0].Score, hits[1].Score)
+	}
+}
+
+func TestSearchPrefixMatch(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Add(&document{ID: 1, Title: "roadmap", Body: "planning for next quarter"})
+
+	if hits := idx.Search("road"); len(hits) != 1 {
+		t.Fatalf("Search(%q) = %d hits, want 1", "road", len(hits))
+	}
+}
+
+func TestSearchPhraseQuery(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Add(&document{ID: 1, Title: "a", Body: "the quick brown fox"})
+	idx.Add(&document{ID: 2, Title: "b", Body: "quick, then brown, then fox"})
+
+	hits := idx.Search(`"quick brown"`)
+	if len(hits) != 1 || hits[0].Doc.ID != 1 {
+		t.Fatalf(`Search(%q) = %v, want exactly doc 1`, `"quick brown"`, hits)
+	}
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Add(&document{ID: 1, Title: "a", Body: "anything"})
+	if hits := idx.Search("   "); hits != nil {
+		t.Errorf("Search of blank query = %v, want nil", hits)
+	}
+}
+
+func TestIndexRemove(t *testing.T) {
+	idx := newSearchIndex()
+	idx.Add(&document{ID: 1, Title: "ephemeral note", Body: "delete me"})
+	idx.Remove(1)
+
+	if hits := idx.Search("ephemeral"); len(hits) != 0 {
+		t.Errorf("Search after Remove = %v, want no hits", hits)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
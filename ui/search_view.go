@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/boba"
+	"github.com/muesli/reflow/indent"
+	te "github.com/muesli/termenv"
+)
+
+// runSearch queries the index and reports ranked hits as a searchResultsMsg.
+// It's re-run on every keystroke while in stateSearch.
+func runSearch(index *searchIndex, query string) boba.Cmd {
+	return func() boba.Msg {
+		if index == nil || strings.TrimSpace(query) == "" {
+			return searchResultsMsg(nil)
+		}
+		return searchResultsMsg(index.Search(query))
+	}
+}
+
+// openDocument loads a document's full body from src and delivers it as a
+// fetchedMarkdownMsg, the same message the stash uses to open a document.
+func openDocument(src stashSource, doc *document) boba.Cmd {
+	return func() boba.Msg {
+		loaded, err := src.Load(doc)
+		if err != nil {
+			return errMsg(err)
+		}
+		return fetchedMarkdownMsg(loaded)
+	}
+}
+
+// searchView renders the query and ranked results. It's effectively the
+// stash list filtered to the live query: rows follow the same cursor and
+// "title — note" convention as stashView, with matched terms highlighted
+// inline in both the title and note, plus a highlighted snippet of body
+// text pulled from around the first match.
+func searchView(m model) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Search: %s█\n\n", m.searchQuery)
+
+	if len(m.searchResults) == 0 {
+		if strings.TrimSpace(m.searchQuery) != "" {
+			b.WriteString("  No matches.\n")
+		}
+		return indent.String(b.String(), 2)
+	}
+
+	terms := tokenize(m.searchQuery)
+	for i, hit := range m.searchResults {
+		cursor := "  "
+		if i == m.searchCursor {
+			cursor = "> "
+		}
+		title := highlightTerms(hit.Doc.Title, terms)
+		if hit.Doc.Note != "" {
+			title = fmt.Sprintf("%s — %s", title, highlightTerms(hit.Doc.Note, terms))
+		}
+		b.WriteString(cursor + title + "\n")
+		if snippet := matchSnippet(hit.Doc.Body, terms); snippet != "" {
+			b.WriteString("    " + snippet + "\n")
+		}
+	}
+
+	return indent.String(b.String(), 2)
+}
+
+// snippetRadius is how many words of body text to show on either side of a
+// match in matchSnippet.
+const snippetRadius = 6
+
+// matchSnippet returns a short, highlighted window of body text around the
+// first word that matches terms, or "" if nothing in body matches.
+func matchSnippet(body string, terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	words := strings.Fields(body)
+	for i, w := range words {
+		if !matchesAnyTerm(w, terms) {
+			continue
+		}
+		start := max(0, i-snippetRadius)
+		end := min(len(words), i+snippetRadius+1)
+		snippet := strings.Join(words[start:end], " ")
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+		if end < len(words) {
+			snippet += "…"
+		}
+		return highlightTerms(snippet, terms)
+	}
+	return ""
+}
+
+func matchesAnyTerm(word string, terms []string) bool {
+	lower := stem(strings.ToLower(word))
+	for _, t := range terms {
+		if strings.HasPrefix(lower, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordPattern matches the runs of letters/digits that tokenize would split
+// text into, so highlightTerms can find and wrap them in place without
+// otherwise touching whitespace, punctuation, or newlines.
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// highlightTerms wraps every word matching terms with reverse-video
+// styling, leaving everything else in text untouched. Unlike splitting on
+// strings.Fields, this preserves the original layout (including newlines),
+// which matters for highlightRendered highlighting multi-line pager output.
+func highlightTerms(text string, terms []string) string {
+	if len(terms) == 0 {
+		return text
+	}
+	return wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		if matchesAnyTerm(word, terms) {
+			return te.String(word).Reverse().String()
+		}
+		return word
+	})
+}
+
+// ansiEscape matches a single ANSI SGR escape sequence, e.g. what Glamour
+// emits for styling.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// highlightRendered highlights terms within text that's already been run
+// through a Renderer, without corrupting the ANSI escape sequences the
+// renderer produced. It splits the input on those escapes, applies
+// highlightTerms only to the literal text in between, and reassembles the
+// result with the original escapes back in place.
+func highlightRendered(rendered string, terms []string) string {
+	if len(terms) == 0 {
+		return rendered
+	}
+	escapes := ansiEscape.FindAllStringIndex(rendered, -1)
+	var b strings.Builder
+	pos := 0
+	for _, loc := range escapes {
+		b.WriteString(highlightTerms(rendered[pos:loc[0]], terms))
+		b.WriteString(rendered[loc[0]:loc[1]])
+		pos = loc[1]
+	}
+	b.WriteString(highlightTerms(rendered[pos:], terms))
+	return b.String()
+}
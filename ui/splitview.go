@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/boba"
+	"github.com/muesli/reflow/ansi"
+)
+
+// splitViewMinWidth is the terminal width, in columns, above which we show
+// the stash and a live preview side by side instead of full-screen panes.
+const splitViewMinWidth = 120
+
+// splitPreviewDebounce is how long the cursor has to rest on a stash entry
+// before we load it into the preview pane, so scrolling quickly past a run
+// of entries doesn't fire off a fetch per row.
+const splitPreviewDebounce = 200 * time.Millisecond
+
+// previewLoadMsg requests that the split-view preview pane load doc, but
+// only if gen still matches the model's current previewGen -- otherwise
+// the cursor has moved on since this was scheduled and it's a no-op.
+type previewLoadMsg struct {
+	gen int
+	doc *document
+}
+
+// debouncedPreviewLoad waits splitPreviewDebounce and then asks for doc to
+// be loaded into the preview pane, tagged with gen so a stale request (the
+// cursor moved again before the wait was up) can be dropped.
+func debouncedPreviewLoad(gen int, doc *document) boba.Cmd {
+	return func() boba.Msg {
+		time.Sleep(splitPreviewDebounce)
+		return previewLoadMsg{gen: gen, doc: doc}
+	}
+}
+
+// splitView renders the stash in the left third of the screen and a live
+// preview of the highlighted document in the remaining width.
+func splitView(m model) string {
+	leftWidth := m.terminalWidth / 3
+	rightWidth := m.terminalWidth - leftWidth
+
+	left := stashView(m.stash)
+	right := pagerView(m.pager)
+
+	return joinHorizontal(left, leftWidth, right, rightWidth)
+}
+
+// joinHorizontal lays out two ANSI-formatted blocks of text side by side,
+// padding each line to its column's width (not counting escape sequences)
+// so the right column lines up. Lines wider than their column are not
+// truncated, and styles left open at the end of a line are not reset.
+func joinHorizontal(left string, leftWidth int, right string, rightWidth int) string {
+	leftLines := strings.Split(left, "\n")
+	rightLines := strings.Split(right, "\n")
+
+	rows := len(leftLines)
+	if len(rightLines) > rows {
+		rows = len(rightLines)
+	}
+
+	var b strings.Builder
+	for i := 0; i < rows; i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		b.WriteString(padToWidth(l, leftWidth))
+		b.WriteString(padToWidth(r, rightWidth))
+		if i < rows-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// padToWidth right-pads s with spaces to width printable columns, measuring
+// width with ansi.PrintableRuneWidth so escape sequences aren't counted.
+func padToWidth(s string, width int) string {
+	w := ansi.PrintableRuneWidth(s)
+	if w >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-w)
+}
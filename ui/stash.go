@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/boba"
+	"github.com/muesli/reflow/indent"
+)
+
+type stashState int
+
+const (
+	stashStateLoading stashState = iota
+	stashStateReady
+	stashStateSettingNote
+	stashStatePromptDelete
+)
+
+// fetchedStashMsg delivers one page of documents from a stashSource.
+type fetchedStashMsg []*document
+
+// noteSavedMsg reports that a note was persisted for doc, so other parts
+// of the UI (the search index, in particular) can pick up the change.
+type noteSavedMsg struct{ Doc *document }
+
+// stashModel is the scrollable list of documents a user can browse, open,
+// annotate, or delete. It's source-agnostic: everything it does goes
+// through the stashSource it was initialized with, so it behaves the same
+// whether that source is a Charm account or a directory of local files.
+type stashModel struct {
+	source    stashSource
+	state     stashState
+	docs      []*document
+	cursor    int
+	noteInput string
+	width     int
+	height    int
+}
+
+// stashInitWithSource creates a stashModel bound to source and kicks off
+// the first fetch.
+func stashInitWithSource(source stashSource) (stashModel, boba.Cmd) {
+	m := stashModel{source: source, state: stashStateLoading}
+	return m, fetchStashPage(source, 0)
+}
+
+// fetchStashPage asks source for a page of documents.
+func fetchStashPage(source stashSource, page int) boba.Cmd {
+	return func() boba.Msg {
+		docs, err := source.Fetch(page)
+		if err != nil {
+			return errMsg(err)
+		}
+		return fetchedStashMsg(docs)
+	}
+}
+
+func (m *stashModel) setSize(w, h int) {
+	m.width = w
+	m.height = h
+}
+
+// selectedDocument returns the document under the cursor, or nil if the
+// stash is empty or still loading.
+func (m stashModel) selectedDocument() *document {
+	if m.cursor < 0 || m.cursor >= len(m.docs) {
+		return nil
+	}
+	return m.docs[m.cursor]
+}
+
+func stashUpdate(msg boba.Msg, m stashModel) (stashModel, boba.Cmd) {
+	switch msg := msg.(type) {
+
+	case fetchedStashMsg:
+		m.docs = msg
+		m.state = stashStateReady
+		if m.cursor >= len(m.docs) {
+			m.cursor = max(0, len(m.docs)-1)
+		}
+
+	case localFileChangedMsg:
+		return m, fetchStashPage(m.source, 0)
+
+	case boba.KeyMsg:
+		switch m.state {
+
+		case stashStateReady:
+			switch msg.String() {
+			case "j", "down":
+				if m.cursor < len(m.docs)-1 {
+					m.cursor++
+				}
+			case "k", "up":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "enter":
+				if sel := m.selectedDocument(); sel != nil {
+					return m, openDocument(m.source, sel)
+				}
+			case "n":
+				if m.selectedDocument() != nil {
+					m.state = stashStateSettingNote
+					m.noteInput = ""
+				}
+			case "x":
+				if m.selectedDocument() != nil {
+					m.state = stashStatePromptDelete
+				}
+			}
+
+		case stashStateSettingNote:
+			switch msg.String() {
+			case "esc", "q":
+				m.state = stashStateReady
+			case "enter":
+				sel := m.selectedDocument()
+				note := m.noteInput
+				source := m.source
+				m.state = stashStateReady
+				if sel != nil {
+					return m, saveNote(source, sel, note)
+				}
+			case "backspace":
+				if len(m.noteInput) > 0 {
+					r := []rune(m.noteInput)
+					m.noteInput = string(r[:len(r)-1])
+				}
+			default:
+				if len(msg.Runes) > 0 && len(m.noteInput) < noteCharacterLimit {
+					m.noteInput += string(msg.Runes)
+				}
+			}
+
+		case stashStatePromptDelete:
+			switch msg.String() {
+			case "y":
+				if sel := m.selectedDocument(); sel != nil {
+					source := m.source
+					m.state = stashStateReady
+					return m, deleteDocument(source, sel.ID)
+				}
+				m.state = stashStateReady
+			case "n", "esc", "q":
+				m.state = stashStateReady
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// saveNote persists note for doc and reports noteSavedMsg once it's
+// saved, so the caller (the search index, in particular) can re-index it.
+func saveNote(source stashSource, doc *document, note string) boba.Cmd {
+	return func() boba.Msg {
+		if err := source.SaveNote(doc.ID, note); err != nil {
+			return errMsg(err)
+		}
+		doc.Note = note
+		return noteSavedMsg{Doc: doc}
+	}
+}
+
+type documentDeletedMsg struct{ ID int }
+
+func deleteDocument(source stashSource, id int) boba.Cmd {
+	return func() boba.Msg {
+		if err := source.Delete(id); err != nil {
+			return errMsg(err)
+		}
+		return documentDeletedMsg{ID: id}
+	}
+}
+
+func stashView(m stashModel) string {
+	switch m.state {
+	case stashStateLoading:
+		return "\n" + indent.String("Loading stash...", 2)
+	case stashStateSettingNote:
+		return "\n" + indent.String(fmt.Sprintf("Note: %s█", m.noteInput), 2)
+	case stashStatePromptDelete:
+		return "\n" + indent.String("Delete this document? (y/n)", 2)
+	}
+
+	if len(m.docs) == 0 {
+		return "\n" + indent.String("Nothing in the stash yet.", 2)
+	}
+
+	var b strings.Builder
+	for i, d := range m.docs {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		title := d.Title
+		if d.Note != "" {
+			title = fmt.Sprintf("%s — %s", title, d.Note)
+		}
+		b.WriteString(cursor + title + "\n")
+	}
+	return "\n" + indent.String(b.String(), 2)
+}
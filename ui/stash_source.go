@@ -0,0 +1,76 @@
+package ui
+
+import "github.com/charmbracelet/charm"
+
+// document is the source-agnostic representation of a single stash entry.
+// Both the Charm-backed source and the filesystem-backed source produce
+// these, so stashModel and pagerModel never need to know which kind of
+// source they're talking to.
+type document struct {
+	ID    int
+	Title string
+	Note  string
+	Body  string
+	Path  string // non-empty for filesystem-backed documents
+}
+
+// stashSource abstracts the fetch/save/delete/note operations stashModel
+// and pagerModel perform against a user's document stash. The Charm client
+// and the filesystem indexer each implement this so the rest of the UI can
+// stay source-agnostic.
+type stashSource interface {
+	// Fetch returns one page of documents, in the source's own order: the
+	// Charm source returns them as the Charm API lists them, the
+	// filesystem source sorts them by path.
+	Fetch(page int) ([]*document, error)
+
+	// Load fetches the full body for a document that was previously
+	// returned (possibly without a body) by Fetch.
+	Load(doc *document) (*document, error)
+
+	// SaveNote persists a new note for the given document.
+	SaveNote(id int, note string) error
+
+	// Delete removes a document from the source.
+	Delete(id int) error
+}
+
+// charmStashSource is the default stashSource, backed by a user's Charm
+// Cloud account. This simply wraps the existing charm.Client calls so
+// behavior is unchanged from before stashSource existed.
+type charmStashSource struct {
+	cc *charm.Client
+}
+
+func newCharmStashSource(cc *charm.Client) *charmStashSource {
+	return &charmStashSource{cc: cc}
+}
+
+func (s *charmStashSource) Fetch(page int) ([]*document, error) {
+	md, err := s.cc.GetStash(page)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*document, len(md))
+	for i, m := range md {
+		docs[i] = &document{ID: m.ID, Title: m.Note, Note: m.Note}
+	}
+	return docs, nil
+}
+
+func (s *charmStashSource) Load(doc *document) (*document, error) {
+	md, err := s.cc.GetStashMarkdown(doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = md.Body
+	return doc, nil
+}
+
+func (s *charmStashSource) SaveNote(id int, note string) error {
+	return s.cc.SetMarkdownNote(id, note)
+}
+
+func (s *charmStashSource) Delete(id int) error {
+	return s.cc.DeleteMarkdown(id)
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/boba"
 	"github.com/charmbracelet/boba/spinner"
@@ -21,9 +22,39 @@ var (
 	glowLogoTextColor = common.Color("#ECFD65")
 )
 
+// Config describes how to set up a Glow program: which Glamour style to
+// render with, and which stashSource to read documents from.
+type Config struct {
+	// Style is the Glamour style to render markdown with ("light", "dark",
+	// or "auto" to detect the terminal background).
+	Style string
+
+	// LocalOnly, when set, skips Charm account initialization and SSH
+	// keygen entirely and serves the stash from LocalPaths instead.
+	LocalOnly bool
+
+	// LocalPaths are the directories (searched recursively for *.md files)
+	// to index when LocalOnly is set.
+	LocalPaths []string
+
+	// ExportFormat, when set ("html" or "pdf"), skips the interactive UI
+	// entirely and renders ExportSourcePath to ExportDestPath instead, so
+	// `glow --export=pdf file.md` can be scripted.
+	ExportFormat string
+
+	// ExportSourcePath is the Markdown file to render when ExportFormat is
+	// set.
+	ExportSourcePath string
+
+	// ExportDestPath is where to write the exported file. If empty, it's
+	// derived from ExportSourcePath by swapping its extension for
+	// ExportFormat's.
+	ExportDestPath string
+}
+
 // NewProgram returns a new Boba program
-func NewProgram(style string) *boba.Program {
-	return boba.NewProgram(initialize(style), update, view)
+func NewProgram(cfg Config) *boba.Program {
+	return boba.NewProgram(initialize(cfg), update, view)
 }
 
 // MESSAGES
@@ -32,6 +63,11 @@ type errMsg error
 type newCharmClientMsg *charm.Client
 type sshAuthErrMsg struct{}
 type terminalResizedMsg struct{}
+type localStashReadyMsg *fsStashSource
+type localFileChangedMsg struct{}
+type searchResultsMsg []searchHit
+type exportDoneMsg struct{ path string }
+type scriptedExportDoneMsg struct{ path string }
 
 type terminalSizeMsg struct {
 	width  int
@@ -52,6 +88,10 @@ const (
 	stateKeygenFinished
 	stateShowStash
 	stateShowDocument
+	stateSearch
+	stateExportPrompt
+	stateExporting
+	stateSplitView
 )
 
 // Stringn translates the staus to a human-readable string. This is just for
@@ -63,12 +103,18 @@ func (s state) String() string {
 		"keygen finished",
 		"showing stash",
 		"showing document",
+		"searching",
+		"prompting for export",
+		"exporting",
+		"showing split view",
 	}[s]
 }
 
 type model struct {
+	cfg            Config
 	cc             *charm.Client
 	user           *charm.User
+	source         stashSource
 	spinner        spinner.Model
 	keygen         keygen.Model
 	state          state
@@ -77,37 +123,83 @@ type model struct {
 	pager          pagerModel
 	terminalWidth  int
 	terminalHeight int
+
+	// search holds state for the "/"-triggered search mode: an in-memory
+	// index over the stash plus whatever the user has typed, the ranked
+	// results for it so far, and which of those results is highlighted.
+	searchIndex          *searchIndex
+	searchQuery          string
+	searchResults        []searchHit
+	searchCursor         int
+	searchHighlightTerms []string
+	preSearchState       state
+
+	// export holds state for the "e"-triggered export prompt: the format
+	// and destination path the user has typed so far.
+	exportInput string
+
+	// splitPreviewID and previewGen track the split-view preview pane: the
+	// ID of the document currently loaded there, and a generation counter
+	// used to drop stale debounced loads when the cursor keeps moving.
+	splitPreviewID int
+	previewGen     int
 }
 
-func (m *model) unloadDocument() {
+func (m *model) unloadDocument() boba.Cmd {
+	if m.terminalWidth > splitViewMinWidth {
+		// The document being closed was promoted from the split view's
+		// preview pane, so drop back into split view with that preview
+		// still loaded, re-rendered at the narrower preview width, rather
+		// than unloading the pager.
+		m.state = stateSplitView
+		m.stash.state = stashStateReady
+		m.pager.setSize(m.terminalWidth-m.terminalWidth/3, m.terminalHeight)
+		if m.pager.currentDocument != nil {
+			return renderWithGlamour(m.pager, m.pager.currentDocument.Body)
+		}
+		return nil
+	}
 	m.state = stateShowStash
 	m.stash.state = stashStateReady
 	m.pager.unload()
+	return nil
 }
 
 // INIT
 
-func initialize(style string) func() (boba.Model, boba.Cmd) {
+func initialize(cfg Config) func() (boba.Model, boba.Cmd) {
 	return func() (boba.Model, boba.Cmd) {
 		s := spinner.NewModel()
 		s.Type = spinner.Dot
 		s.ForegroundColor = common.SpinnerColor
 
-		if style == "auto" {
+		if cfg.Style == "auto" {
 			dbg := te.HasDarkBackground()
 			if dbg == true {
-				style = "dark"
+				cfg.Style = "dark"
 			} else {
-				style = "light"
+				cfg.Style = "light"
 			}
 		}
 
+		if cfg.ExportFormat != "" {
+			// Scripted export: render one file and exit, skipping Charm
+			// client/keygen setup and the interactive UI entirely.
+			return model{cfg: cfg, spinner: s, state: stateExporting}, scriptedExport(cfg)
+		}
+
+		initCmd := newCharmClient
+		if cfg.LocalOnly {
+			initCmd = initLocalStash(cfg.LocalPaths)
+		}
+
 		return model{
+				cfg:     cfg,
 				spinner: s,
-				pager:   newPagerModel(style),
+				pager:   newPagerModel(cfg.Style),
 				state:   stateInitCharmClient,
 			}, boba.Batch(
-				newCharmClient,
+				initCmd,
 				spinner.Tick(s),
 				getTerminalSize(),
 				listenForTerminalResize(),
@@ -133,16 +225,127 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 	switch msg := msg.(type) {
 
 	case boba.KeyMsg:
+		// Search mode captures nearly all keys itself (they're query text),
+		// so it's handled before the general switch below.
+		if m.state == stateSearch {
+			switch msg.String() {
+			case "q":
+				fallthrough
+			case "esc":
+				m.state = m.preSearchState
+				m.searchQuery = ""
+				m.searchResults = nil
+				m.searchCursor = 0
+				return m, nil
+			case "down":
+				if m.searchCursor < len(m.searchResults)-1 {
+					m.searchCursor++
+				}
+				return m, nil
+			case "up":
+				if m.searchCursor > 0 {
+					m.searchCursor--
+				}
+				return m, nil
+			case "enter":
+				if len(m.searchResults) == 0 || m.source == nil {
+					return m, nil
+				}
+				sel := m.searchResults[m.searchCursor]
+				m.state = stateShowDocument
+				m.searchHighlightTerms = tokenize(m.searchQuery)
+				return m, openDocument(m.source, sel.Doc)
+			case "backspace":
+				if len(m.searchQuery) > 0 {
+					r := []rune(m.searchQuery)
+					m.searchQuery = string(r[:len(r)-1])
+				}
+				m.searchCursor = 0
+				return m, runSearch(m.searchIndex, m.searchQuery)
+			case "ctrl+c":
+				return m, boba.Quit
+			default:
+				if len(msg.Runes) > 0 {
+					m.searchQuery += string(msg.Runes)
+					m.searchCursor = 0
+					return m, runSearch(m.searchIndex, m.searchQuery)
+				}
+				return m, nil
+			}
+		}
+
+		// Export prompt: the user types "<format> <path>", e.g. "pdf
+		// notes.pdf", and enter kicks off the export.
+		if m.state == stateExportPrompt {
+			switch msg.String() {
+			case "q":
+				fallthrough
+			case "esc":
+				m.state = stateShowDocument
+				m.exportInput = ""
+				return m, nil
+			case "enter":
+				fields := strings.Fields(m.exportInput)
+				if len(fields) != 2 {
+					m.err = errors.New("usage: <format> <path>, e.g. pdf notes.pdf")
+					return m, nil
+				}
+				format, err := parseExportFormat(fields[0])
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.state = stateExporting
+				return m, doExport(format, m.cfg.Style, m.terminalWidth, m.pager.currentDocument.Body, fields[1])
+			case "backspace":
+				if len(m.exportInput) > 0 {
+					r := []rune(m.exportInput)
+					m.exportInput = string(r[:len(r)-1])
+				}
+				return m, nil
+			case "ctrl+c":
+				return m, boba.Quit
+			default:
+				if len(msg.Runes) > 0 {
+					m.exportInput += string(msg.Runes)
+				}
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "f":
 			m.err = errors.New("Fatal.")
+		case "e":
+			if m.state == stateShowDocument && m.pager.state == pagerStateBrowse {
+				m.state = stateExportPrompt
+			}
+		case "/":
+			if (m.state == stateShowStash || m.state == stateSplitView) && m.searchIndex != nil {
+				m.preSearchState = m.state
+				m.state = stateSearch
+				m.searchQuery = ""
+				m.searchResults = nil
+			}
+		case "enter":
+			// In split view the preview pane already has the highlighted
+			// document loaded; promote it to a full-screen read instead of
+			// re-opening it (which would just refresh the same preview),
+			// re-rendering at the wider full-screen width.
+			if m.state == stateSplitView && m.stash.state == stashStateReady {
+				m.state = stateShowDocument
+				m.pager.setSize(m.terminalWidth, m.terminalHeight)
+				if m.pager.currentDocument != nil {
+					cmds = append(cmds, renderWithGlamour(m.pager, m.pager.currentDocument.Body))
+				}
+			}
 		case "q":
 			fallthrough
 		case "esc":
 			var cmd boba.Cmd
 
 			switch m.state {
-			case stateShowStash:
+			case stateShowStash, stateSplitView:
 
 				switch m.stash.state {
 				case stashStateSettingNote:
@@ -154,7 +357,7 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 
 			case stateShowDocument:
 				if m.pager.state == pagerStateBrowse {
-					m.unloadDocument() // exits pager
+					cmd = m.unloadDocument() // exits pager (or drops back to split view)
 				} else {
 					m.pager, cmd = pagerUpdate(msg, m.pager)
 				}
@@ -173,8 +376,16 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 
 	case errMsg:
 		m.err = msg
+		if m.cfg.ExportFormat != "" {
+			// A scripted export failed; there's no interactive UI to fall
+			// back into, so just exit.
+			return m, boba.Quit
+		}
 		return m, nil
 
+	case scriptedExportDoneMsg:
+		return m, boba.Quit
+
 	case terminalResizedMsg:
 		cmds = append(cmds,
 			getTerminalSize(),
@@ -188,8 +399,22 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 		w, h := msg.Size()
 		m.terminalWidth = w
 		m.terminalHeight = h
-		m.stash.setSize(w, h)
-		m.pager.setSize(w, h)
+
+		if w > splitViewMinWidth {
+			if m.state == stateShowStash {
+				m.state = stateSplitView
+			}
+			if m.state == stateSplitView {
+				m.stash.setSize(w/3, h)
+				m.pager.setSize(w-w/3, h)
+			}
+		} else {
+			if m.state == stateSplitView {
+				m.state = stateShowStash
+			}
+			m.stash.setSize(w, h)
+			m.pager.setSize(w, h)
+		}
 
 		// TODO: load more stash pages if we've resized, are on the last page,
 		// and haven't loaded more pages yet.
@@ -208,7 +433,7 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 
 	case spinner.TickMsg:
 		switch m.state {
-		case stateInitCharmClient:
+		case stateInitCharmClient, stateExporting:
 			m.spinner, cmd = spinner.Update(msg, m.spinner)
 		}
 		cmds = append(cmds, cmd)
@@ -218,26 +443,97 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 		cmds = append(cmds, newCharmClient)
 
 	case noteSavedMsg:
-		// A note was saved to a document. This will have be done in the
-		// pager, so we'll need to find the corresponding note in the stash.
-		// So, pass the message to the stash for processing.
+		// A note was saved to a document. Pass it along to the stash for
+		// processing and keep the search index's copy of the note in sync.
 		m.stash, cmd = stashUpdate(msg, m.stash)
 		cmds = append(cmds, cmd)
+		if m.searchIndex != nil && msg.Doc != nil {
+			m.searchIndex.Add(msg.Doc)
+		}
 
 	case newCharmClientMsg:
 		m.cc = msg
+		m.source = newCharmStashSource(msg)
+		m.searchIndex = newSearchIndex()
 		m.state = stateShowStash
-		m.stash, cmd = stashInit(msg)
+		m.stash, cmd = stashInitWithSource(m.source)
 		m.stash.setSize(m.terminalWidth, m.terminalHeight)
 		m.pager.cc = msg
 		cmds = append(cmds, cmd)
 
+	case localStashReadyMsg:
+		m.source = msg
+		m.searchIndex = newSearchIndex()
+		m.state = stateShowStash
+		m.stash, cmd = stashInitWithSource(m.source)
+		m.stash.setSize(m.terminalWidth, m.terminalHeight)
+		cmds = append(cmds, cmd, watchLocalStash(msg))
+
+	case searchResultsMsg:
+		m.searchResults = msg
+		if m.searchCursor >= len(m.searchResults) {
+			m.searchCursor = max(0, len(m.searchResults)-1)
+		}
+
+	case documentDeletedMsg:
+		if m.searchIndex != nil {
+			m.searchIndex.Remove(msg.ID)
+		}
+		cmds = append(cmds, fetchStashPage(m.source, 0))
+
+	case localFileChangedMsg:
+		// A file changed under one of the watched directories. Reindex
+		// from disk first so the refetch below (triggered by stashUpdate)
+		// sees the new state, then keep watching for further changes.
+		if src, ok := m.source.(*fsStashSource); ok {
+			if err := src.reindex(); err != nil {
+				m.err = err
+			}
+			m.stash, cmd = stashUpdate(msg, m.stash)
+			cmds = append(cmds, cmd, watchLocalStash(src))
+		}
+
+	case fetchedStashMsg:
+		// Index every document as it arrives so search has titles and
+		// notes available even before a document's body is loaded.
+		if m.searchIndex != nil {
+			for _, d := range msg {
+				m.searchIndex.Add(d)
+			}
+		}
+
 	case fetchedMarkdownMsg:
 		m.pager.currentDocument = msg
+		// Carry over the terms that were highlighted in the search results
+		// list, if this document was opened from one, so the pager
+		// highlights the same matches; otherwise clear any terms left over
+		// from a previous search-opened document.
+		m.pager.highlightTerms = m.searchHighlightTerms
+		m.searchHighlightTerms = nil
 		cmds = append(cmds, renderWithGlamour(m.pager, msg.Body))
+		if m.searchIndex != nil {
+			m.searchIndex.Add((*document)(msg))
+		}
 
 	case contentRenderedMsg:
+		// In split view the pager is just the preview pane, so a freshly
+		// rendered document shouldn't take over the whole screen.
+		if m.state != stateSplitView {
+			m.state = stateShowDocument
+		} else {
+			m.pager, cmd = pagerUpdate(msg, m.pager)
+			cmds = append(cmds, cmd)
+		}
+
+	case exportDoneMsg:
+		// Exported successfully; drop back into the pager.
 		m.state = stateShowDocument
+		m.exportInput = ""
+
+	case previewLoadMsg:
+		if msg.gen == m.previewGen && m.source != nil {
+			cmds = append(cmds, openDocument(m.source, msg.doc))
+		}
 
 	}
 
@@ -259,6 +555,18 @@ func update(msg boba.Msg, mdl boba.Model) (boba.Model, boba.Cmd) {
 		m.stash, cmd = stashUpdate(msg, m.stash)
 		cmds = append(cmds, cmd)
 
+	case stateSplitView:
+		// Process stash navigation, then check whether the highlighted
+		// entry changed so the preview pane can catch up.
+		m.stash, cmd = stashUpdate(msg, m.stash)
+		cmds = append(cmds, cmd)
+
+		if sel := m.stash.selectedDocument(); sel != nil && sel.ID != m.splitPreviewID {
+			m.splitPreviewID = sel.ID
+			m.previewGen++
+			cmds = append(cmds, debouncedPreviewLoad(m.previewGen, sel))
+		}
+
 	case stateShowDocument:
 		// Process pager
 		m.pager, cmd = pagerUpdate(msg, m.pager)
@@ -292,6 +600,14 @@ func view(mdl boba.Model) string {
 		return stashView(m.stash)
 	case stateShowDocument:
 		return pagerView(m.pager)
+	case stateSearch:
+		return searchView(m)
+	case stateExportPrompt:
+		s += fmt.Sprintf("Export as (format path): %s█", m.exportInput)
+	case stateExporting:
+		s += spinner.View(m.spinner) + " Exporting..."
+	case stateSplitView:
+		return splitView(m)
 	}
 
 	return "\n" + indent.String(s, 2)
@@ -327,6 +643,29 @@ func newCharmClient() boba.Msg {
 	return newCharmClientMsg(cc)
 }
 
+// initLocalStash indexes the given directories and returns a stashSource
+// backed by the filesystem, bypassing Charm client initialization and SSH
+// keygen entirely.
+func initLocalStash(dirs []string) boba.Cmd {
+	return func() boba.Msg {
+		src, err := newFSStashSource(dirs)
+		if err != nil {
+			return errMsg(err)
+		}
+		return localStashReadyMsg(src)
+	}
+}
+
+// watchLocalStash waits for the next filesystem change under a local
+// stashSource's watched directories and reports it as a message so the
+// stash can be refreshed.
+func watchLocalStash(src *fsStashSource) boba.Cmd {
+	return func() boba.Msg {
+		<-src.Events()
+		return localFileChangedMsg{}
+	}
+}
+
 // ETC
 
 func min(a, b int) int {